@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ExitError is returned by a command handler to request a specific
+// process exit code. main honors it via os.Exit; batch mode stops the
+// run at once and returns its code.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// RunBatch executes the commands read from r sequentially, as if typed
+// at the interactive prompt, and returns the process exit code to use:
+// the exit status of the last command run, mirroring how a shell
+// script's own exit code tracks its last statement. A success resets
+// the code to 0, so a failure does not stick around past a later
+// success. An `exit [code]` command stops the run immediately and its
+// code wins outright; it is not treated as a failure. Blank lines and
+// "#"-prefixed comments are skipped. If abortOnError is set, the first
+// failing command stops the run, so its code is what's returned.
+func (el *EventLoop) RunBatch(r io.Reader, abortOnError bool) int {
+	scanner := bufio.NewScanner(r)
+	code := 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := el.processCommand(el.interpolate(line)); err != nil {
+			var exitErr *ExitError
+			if errors.As(err, &exitErr) {
+				return exitErr.Code
+			}
+			code = exitCodeOf(err)
+			fmt.Printf("Error: %v\n", err)
+			if abortOnError {
+				break
+			}
+		} else {
+			code = 0
+		}
+
+		select {
+		case <-el.ctx.Done():
+			return code
+		default:
+		}
+	}
+
+	return code
+}
+
+// exitCodeOf extracts the real process exit code from err, unwrapping
+// an *exec.ExitError so a failing external command (e.g. via `run`)
+// propagates its own status rather than a hardcoded 1. Any other kind
+// of error (bad usage, unknown command, ...) defaults to 1.
+func exitCodeOf(err error) int {
+	var procErr *exec.ExitError
+	if errors.As(err, &procErr) {
+		return procErr.ExitCode()
+	}
+	return 1
+}
+
+// splitOneShot splits a `-c "cmd; cmd"` argument into individual
+// commands on ';', mirroring the statement separator a shell would
+// use for a one-liner.
+func splitOneShot(script string) []string {
+	parts := strings.Split(script, ";")
+	cmds := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			cmds = append(cmds, p)
+		}
+	}
+	return cmds
+}
+
+// parseExitArgs parses the optional numeric argument to the `exit`
+// command, defaulting to code 0.
+func parseExitArgs(args []string) int {
+	if len(args) == 0 {
+		return 0
+	}
+	code, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0
+	}
+	return code
+}
+
+// parseFlags parses the top-level -c/-f/-e flags used to put Ralphussy
+// into non-interactive batch mode.
+func parseFlags(args []string) (script, scriptFile string, abortOnError bool) {
+	fs := flag.NewFlagSet("ralphussy", flag.ExitOnError)
+	fs.StringVar(&script, "c", "", "execute the given `commands` (separated by ';') and exit")
+	fs.StringVar(&scriptFile, "f", "", "execute commands read from `file` and exit")
+	fs.BoolVar(&abortOnError, "e", false, "abort the script on the first failing command")
+	fs.Parse(args)
+	return script, scriptFile, abortOnError
+}
+
+// isTerminal reports whether f is connected to a terminal, used to
+// decide whether to drop into batch mode for piped stdin.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitOneShot(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"single command", "echo hi", []string{"echo hi"}},
+		{"multiple commands", "echo hi; echo bye", []string{"echo hi", "echo bye"}},
+		{"blank segments skipped", "echo hi;; echo bye;", []string{"echo hi", "echo bye"}},
+		{"whitespace trimmed", "  echo hi  ;  echo bye  ", []string{"echo hi", "echo bye"}},
+		{"empty input", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitOneShot(tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("splitOneShot(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("splitOneShot(%q)[%d] = %q, want %q", tt.input, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseExitArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no args", nil, 0},
+		{"valid code", []string{"7"}, 7},
+		{"non-numeric defaults to zero", []string{"nope"}, 0},
+		{"extra args ignored", []string{"3", "ignored"}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseExitArgs(tt.args); got != tt.want {
+				t.Errorf("parseExitArgs(%v) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunBatchExitCommandStopsImmediately(t *testing.T) {
+	el := NewEventLoop()
+
+	code := el.RunBatch(strings.NewReader("echo hi\nexit 5\necho never\n"), false)
+	if code != 5 {
+		t.Errorf("RunBatch() = %d, want 5", code)
+	}
+}
+
+func TestRunBatchLastStatusWins(t *testing.T) {
+	el := NewEventLoop()
+
+	code := el.RunBatch(strings.NewReader("run /bin/false\necho hi\n"), false)
+	if code != 0 {
+		t.Errorf("RunBatch() = %d, want 0: a later success should reset the exit code", code)
+	}
+}
+
+func TestRunBatchPropagatesRealExitCode(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "exit7.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nexit 7\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	el := NewEventLoop()
+	code := el.RunBatch(strings.NewReader("run "+script+"\n"), false)
+	if code != 7 {
+		t.Errorf("RunBatch() = %d, want 7 (the script's own exit code)", code)
+	}
+}
+
+func TestRunBatchAbortOnError(t *testing.T) {
+	el := NewEventLoop()
+
+	code := el.RunBatch(strings.NewReader("run /bin/false\necho should-not-run\n"), true)
+	if code != 1 {
+		t.Errorf("RunBatch() = %d, want 1", code)
+	}
+}
+
+func TestRunBatchSkipsBlankLinesAndComments(t *testing.T) {
+	el := NewEventLoop()
+
+	code := el.RunBatch(strings.NewReader("# a comment\n\necho hi\n"), false)
+	if code != 0 {
+		t.Errorf("RunBatch() = %d, want 0", code)
+	}
+}
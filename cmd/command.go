@@ -0,0 +1,207 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Command describes a single entry in the EventLoop's command registry.
+type Command struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Usage       string
+	// FlagSet, if set, is called once per invocation to build a fresh
+	// *flag.FlagSet for parsing this command's arguments. Returning the
+	// same *flag.FlagSet across calls would leak state between runs.
+	FlagSet func() *flag.FlagSet
+	Handler func(el *EventLoop, args []string) error
+	// Subcommands, if set, routes to a child command by the next
+	// token (e.g. "help usage echo" routes "usage echo" to the
+	// "usage" subcommand of "help"). Handler still runs when no
+	// token matches a subcommand name, so a command can support both
+	// a bare form and subcommands.
+	Subcommands *Registry
+}
+
+// Registry holds the set of commands an EventLoop can dispatch to, keyed
+// by both their primary name and any aliases.
+type Registry struct {
+	commands map[string]*Command
+	order    []*Command
+}
+
+// NewRegistry returns an empty command registry.
+func NewRegistry() *Registry {
+	return &Registry{commands: make(map[string]*Command)}
+}
+
+// Register adds cmd to the registry under its name and all aliases.
+// Registering a name or alias that already exists overwrites the prior
+// entry, so built-ins can be replaced by callers that register later.
+func (r *Registry) Register(cmd *Command) {
+	r.commands[cmd.Name] = cmd
+	for _, alias := range cmd.Aliases {
+		r.commands[alias] = cmd
+	}
+	for i, prior := range r.order {
+		if prior.Name == cmd.Name {
+			r.order[i] = cmd
+			return
+		}
+	}
+	r.order = append(r.order, cmd)
+}
+
+// Lookup returns the command registered under name or alias, if any.
+func (r *Registry) Lookup(name string) (*Command, bool) {
+	cmd, ok := r.commands[name]
+	return cmd, ok
+}
+
+// All returns the registered commands in registration order, with
+// duplicates from aliasing collapsed.
+func (r *Registry) All() []*Command {
+	return r.order
+}
+
+// Dispatch parses input into a command name and arguments, looks the
+// command up in the registry, and runs it.
+func (r *Registry) Dispatch(el *EventLoop, input string) error {
+	parts := strings.Fields(input)
+	if len(parts) == 0 {
+		return nil
+	}
+
+	name, args := parts[0], parts[1:]
+
+	cmd, ok := r.Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown command: %s (type 'help' for available commands)", name)
+	}
+
+	return runCommand(el, cmd, args)
+}
+
+// runCommand executes cmd with args. If cmd has Subcommands and the
+// next token names one, it routes there instead (recursively, so
+// subcommands can themselves have subcommands); otherwise it handles
+// per-command "--help"/"-h" and flag parsing before invoking cmd's own
+// Handler, so neither handlers nor subcommands need to special-case
+// "--help".
+func runCommand(el *EventLoop, cmd *Command, args []string) error {
+	if cmd.Subcommands != nil && len(args) > 0 {
+		if sub, ok := cmd.Subcommands.Lookup(args[0]); ok {
+			return runCommand(el, sub, args[1:])
+		}
+	}
+
+	for _, arg := range args {
+		if arg == "--help" || arg == "-h" {
+			printUsage(cmd)
+			return nil
+		}
+	}
+
+	if cmd.FlagSet != nil {
+		fs := cmd.FlagSet()
+		fs.Usage = func() { printUsage(cmd) }
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		args = fs.Args()
+	}
+
+	return cmd.Handler(el, args)
+}
+
+func printUsage(cmd *Command) {
+	fmt.Printf("usage: %s\n", cmd.Usage)
+	if cmd.Description != "" {
+		fmt.Println(cmd.Description)
+	}
+	if len(cmd.Aliases) > 0 {
+		fmt.Printf("aliases: %s\n", strings.Join(cmd.Aliases, ", "))
+	}
+}
+
+// registerBuiltins installs the commands that ship with the terminal.
+func registerBuiltins(el *EventLoop) {
+	el.Register(&Command{
+		Name:        "exit",
+		Aliases:     []string{"quit"},
+		Description: "Exit the terminal, optionally with a status code",
+		Usage:       "exit [code]",
+		Handler: func(el *EventLoop, args []string) error {
+			el.cancel()
+			if code := parseExitArgs(args); code != 0 {
+				return &ExitError{Code: code}
+			}
+			return nil
+		},
+	})
+
+	helpSubs := NewRegistry()
+	helpSubs.Register(&Command{
+		Name:        "usage",
+		Description: "Show the usage line for a specific command",
+		Usage:       "help usage <command>",
+		Handler: func(el *EventLoop, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("usage: help usage <command>")
+			}
+			cmd, ok := el.registry.Lookup(args[0])
+			if !ok {
+				return fmt.Errorf("unknown command: %s", args[0])
+			}
+			printUsage(cmd)
+			return nil
+		},
+	})
+
+	el.Register(&Command{
+		Name:        "help",
+		Description: "Show available commands",
+		Usage:       "help [usage <command>]",
+		Subcommands: helpSubs,
+		Handler: func(el *EventLoop, args []string) error {
+			el.showHelp()
+			return nil
+		},
+	})
+
+	// noNewline is set by echo's FlagSet closure on each invocation and
+	// read by its Handler; registerBuiltins runs once per EventLoop and
+	// commands are dispatched one at a time, so the shared var is safe.
+	var noNewline bool
+	el.Register(&Command{
+		Name:        "echo",
+		Description: "Echo back the provided text",
+		Usage:       "echo [-n] [text...]",
+		FlagSet: func() *flag.FlagSet {
+			fs := flag.NewFlagSet("echo", flag.ContinueOnError)
+			fs.BoolVar(&noNewline, "n", false, "suppress the trailing newline")
+			return fs
+		},
+		Handler: func(el *EventLoop, args []string) error {
+			text := strings.Join(args, " ")
+			if noNewline {
+				fmt.Print(text)
+			} else {
+				fmt.Println(text)
+			}
+			return nil
+		},
+	})
+}
+
+func (el *EventLoop) showHelp() {
+	fmt.Println("Available commands:")
+	cmds := append([]*Command(nil), el.registry.All()...)
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	for _, cmd := range cmds {
+		fmt.Printf("  %-8s- %s\n", cmd.Name, cmd.Description)
+	}
+}
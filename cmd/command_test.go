@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStdout redirects os.Stdout for the duration of fn and returns
+// everything written to it, since the registry's builtins print
+// straight to os.Stdout rather than taking a writer.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	orig := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	os.Stdout = w
+
+	fn()
+
+	w.Close()
+	os.Stdout = orig
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatalf("read captured stdout: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRegistryDispatchEcho(t *testing.T) {
+	el := NewEventLoop()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "echo hello world", "hello world\n"},
+		{"suppresses newline with -n", "echo -n hi", "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureStdout(t, func() {
+				if err := el.registry.Dispatch(el, tt.input); err != nil {
+					t.Fatalf("Dispatch(%q): %v", tt.input, err)
+				}
+			})
+			if out != tt.want {
+				t.Errorf("Dispatch(%q) output = %q, want %q", tt.input, out, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryDispatchUnknownCommand(t *testing.T) {
+	el := NewEventLoop()
+
+	if err := el.registry.Dispatch(el, "nope"); err == nil {
+		t.Fatal("Dispatch(\"nope\") = nil error, want an unknown-command error")
+	}
+}
+
+func TestRegistryDispatchHelpFlag(t *testing.T) {
+	el := NewEventLoop()
+
+	out := captureStdout(t, func() {
+		if err := el.registry.Dispatch(el, "echo --help"); err != nil {
+			t.Fatalf("Dispatch: %v", err)
+		}
+	})
+	if !strings.Contains(out, "usage: echo") {
+		t.Errorf("Dispatch(\"echo --help\") output = %q, want it to contain echo's usage", out)
+	}
+}
+
+func TestRegistryDispatchSubcommand(t *testing.T) {
+	el := NewEventLoop()
+
+	out := captureStdout(t, func() {
+		if err := el.registry.Dispatch(el, "help usage echo"); err != nil {
+			t.Fatalf("Dispatch: %v", err)
+		}
+	})
+	if !strings.Contains(out, "usage: echo") {
+		t.Errorf("Dispatch(\"help usage echo\") output = %q, want it to contain echo's usage", out)
+	}
+}
+
+func TestRegistryDispatchBareCommandWithSubcommands(t *testing.T) {
+	el := NewEventLoop()
+
+	// With no trailing token, "help" should run its own Handler rather
+	// than trying (and failing) to route into a subcommand.
+	out := captureStdout(t, func() {
+		if err := el.registry.Dispatch(el, "help"); err != nil {
+			t.Fatalf("Dispatch: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Available commands:") {
+		t.Errorf("Dispatch(\"help\") output = %q, want the bare help listing", out)
+	}
+}
+
+func TestRegistryDispatchUnmatchedTokenFallsThroughToHandler(t *testing.T) {
+	el := NewEventLoop()
+
+	// "bogus" doesn't name a subcommand of "help", so it should be
+	// passed through to help's own Handler as an ordinary argument
+	// rather than producing an "unknown command" error.
+	out := captureStdout(t, func() {
+		if err := el.registry.Dispatch(el, "help bogus"); err != nil {
+			t.Fatalf("Dispatch: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Available commands:") {
+		t.Errorf("Dispatch(\"help bogus\") output = %q, want the bare help listing", out)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runExternal shells out via CommandContext so the child process is
+// killed when ctx is canceled (e.g. by Ctrl+C), rather than leaking
+// past a canceled command. Stdout/stderr stream live to the terminal.
+func runExternal(el *EventLoop, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: run <command> [args...]")
+	}
+
+	cmd := exec.CommandContext(el.cmdCtx, args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// CaptureOutput runs name with args and returns its combined stdout
+// and stderr, trimmed. It pipes both through one os.Pipe and
+// copies it in a background goroutine so output is captured even if
+// the child writes directly to the fd rather than buffering.
+func CaptureOutput(ctx context.Context, name string, args ...string) (string, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	var buf bytes.Buffer
+	copyDone := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(&buf, r)
+		copyDone <- err
+	}()
+
+	runErr := cmd.Run()
+	w.Close()
+	<-copyDone
+	r.Close()
+
+	if runErr != nil {
+		return strings.TrimSpace(buf.String()), runErr
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// capture implements the `capture <var> <cmd...>` command: it stores
+// the trimmed output of cmd into the named session variable.
+func capture(el *EventLoop, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: capture <var> <cmd...>")
+	}
+
+	name := args[0]
+	out, err := CaptureOutput(el.cmdCtx, args[1], args[2:]...)
+	el.vars[name] = out
+	if err != nil {
+		return fmt.Errorf("capture %s: %w", name, err)
+	}
+	return nil
+}
+
+// interpolate replaces $var references in input with the value of the
+// matching session variable, giving the shell basic scripting power.
+func (el *EventLoop) interpolate(input string) string {
+	var out strings.Builder
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '$' || i == len(runes)-1 {
+			out.WriteRune(runes[i])
+			continue
+		}
+
+		j := i + 1
+		for j < len(runes) && isVarNameRune(runes[j]) {
+			j++
+		}
+		if j == i+1 {
+			out.WriteRune(runes[i])
+			continue
+		}
+
+		name := string(runes[i+1 : j])
+		out.WriteString(el.vars[name])
+		i = j - 1
+	}
+
+	return out.String()
+}
+
+func isVarNameRune(r rune) bool {
+	return r == '_' ||
+		(r >= 'a' && r <= 'z') ||
+		(r >= 'A' && r <= 'Z') ||
+		(r >= '0' && r <= '9')
+}
+
+func registerExecCommands(el *EventLoop) {
+	el.Register(&Command{
+		Name:        "run",
+		Aliases:     []string{"!"},
+		Description: "Run an external command, streaming its output",
+		Usage:       "run <command> [args...]",
+		Handler:     runExternal,
+	})
+
+	el.Register(&Command{
+		Name:        "capture",
+		Description: "Run a command and store its trimmed output in a session variable",
+		Usage:       "capture <var> <cmd...>",
+		Handler:     capture,
+	})
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestInterpolate(t *testing.T) {
+	el := &EventLoop{vars: map[string]string{"name": "world", "a1_b": "ok"}}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"simple substitution", "hello $name", "hello world"},
+		{"no variables", "hello there", "hello there"},
+		{"unset variable becomes empty", "hello $missing", "hello "},
+		{"trailing dollar is literal", "cost is $", "cost is $"},
+		{"adjacent variables", "$name$name", "worldworld"},
+		{"digits and underscore in name", "val=$a1_b", "val=ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := el.interpolate(tt.input); got != tt.want {
+				t.Errorf("interpolate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
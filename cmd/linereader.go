@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 127
+	keyEnter     = '\r'
+	keyEscape    = 27
+	keyTab       = '\t'
+)
+
+// errInterrupted is returned by ReadLine when the user presses Ctrl+C.
+var errInterrupted = fmt.Errorf("interrupted")
+
+// Completer returns the set of completion candidates for the text typed
+// so far on the current line. The command registry populates this from
+// registered command names.
+type Completer func(line string) []string
+
+// LineReader is a small raw-mode line editor: arrow-key history
+// navigation, persistent history file, and pluggable tab-completion,
+// in the spirit of liner/go-prompt but scoped to what Ralphussy needs.
+type LineReader struct {
+	in          *os.File
+	out         *os.File
+	historyPath string
+	history     []string
+	completer   Completer
+
+	// stateMu guards oldState, which is read and written from both the
+	// goroutine driving ReadLine and the signal-handling goroutine
+	// calling Suspend/Resume on SIGTSTP.
+	stateMu  sync.Mutex
+	oldState *term.State
+}
+
+// NewLineReader builds a LineReader reading from os.Stdin and writing
+// prompts/echo to os.Stdout. historyPath is expanded with
+// DefaultHistoryFile if empty.
+func NewLineReader(historyPath string, completer Completer) *LineReader {
+	if historyPath == "" {
+		historyPath = DefaultHistoryFile()
+	}
+	lr := &LineReader{
+		in:          os.Stdin,
+		out:         os.Stdout,
+		historyPath: historyPath,
+		completer:   completer,
+	}
+	lr.loadHistory()
+	return lr
+}
+
+// DefaultHistoryFile returns ~/.ralphussy_history, falling back to a
+// relative path if the home directory can't be determined.
+func DefaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".ralphussy_history"
+	}
+	return filepath.Join(home, ".ralphussy_history")
+}
+
+func (lr *LineReader) loadHistory() {
+	f, err := os.Open(lr.historyPath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			lr.history = append(lr.history, line)
+		}
+	}
+}
+
+func (lr *LineReader) appendHistory(line string) {
+	lr.history = append(lr.history, line)
+
+	f, err := os.OpenFile(lr.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// ReadLine reads a single line of input in raw mode, supporting
+// Up/Down history navigation and Tab completion. It returns
+// ctx.Err() if ctx is canceled before the line is complete, and
+// io.EOF on Ctrl+D with an empty line.
+func (lr *LineReader) ReadLine(ctx context.Context, prompt string) (string, error) {
+	fd := int(lr.in.Fd())
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return lr.readLineFallback(ctx, prompt)
+	}
+	lr.stateMu.Lock()
+	lr.oldState = state
+	lr.stateMu.Unlock()
+	defer lr.restore(fd)
+
+	fmt.Fprint(lr.out, prompt)
+
+	type result struct {
+		line string
+		err  error
+	}
+	resCh := make(chan result, 1)
+
+	go func() {
+		line, err := lr.editLine(prompt)
+		resCh <- result{line, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		lr.restore(fd)
+		return "", ctx.Err()
+	case res := <-resCh:
+		if res.err == nil && res.line != "" {
+			lr.appendHistory(res.line)
+		}
+		return res.line, res.err
+	}
+}
+
+// restore puts the terminal back into cooked mode. It is safe to call
+// more than once, and safe to call concurrently with Suspend/Resume.
+func (lr *LineReader) restore(fd int) {
+	lr.stateMu.Lock()
+	state := lr.oldState
+	lr.oldState = nil
+	lr.stateMu.Unlock()
+
+	if state == nil {
+		return
+	}
+	term.Restore(fd, state)
+}
+
+// Suspend restores cooked terminal mode ahead of the process
+// self-suspending on SIGTSTP, so the shell doesn't regain control of a
+// terminal still left in raw mode.
+func (lr *LineReader) Suspend() {
+	lr.restore(int(lr.in.Fd()))
+}
+
+// Resume re-enters raw mode after the process resumes from SIGCONT.
+func (lr *LineReader) Resume() {
+	state, err := term.MakeRaw(int(lr.in.Fd()))
+	if err != nil {
+		return
+	}
+	lr.stateMu.Lock()
+	lr.oldState = state
+	lr.stateMu.Unlock()
+}
+
+// readLineFallback is used when the terminal can't be put into raw mode
+// (e.g. stdin isn't a real terminal); it reads a plain line with no
+// editing, history navigation or completion.
+func (lr *LineReader) readLineFallback(ctx context.Context, prompt string) (string, error) {
+	fmt.Fprint(lr.out, prompt)
+
+	type result struct {
+		line string
+		err  error
+	}
+	resCh := make(chan result, 1)
+
+	go func() {
+		reader := bufio.NewReader(lr.in)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" {
+				resCh <- result{"", io.EOF}
+				return
+			}
+		}
+		resCh <- result{strings.TrimRight(line, "\r\n"), nil}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-resCh:
+		return res.line, res.err
+	}
+}
+
+// editLine runs the character-at-a-time edit loop. It assumes the
+// terminal is already in raw mode.
+func (lr *LineReader) editLine(prompt string) (string, error) {
+	var buf []rune
+	histPos := len(lr.history)
+	reader := bufio.NewReader(lr.in)
+
+	redraw := func() {
+		fmt.Fprintf(lr.out, "\r\x1b[K%s%s", prompt, string(buf))
+	}
+
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyCtrlC:
+			fmt.Fprintln(lr.out)
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(buf) == 0 {
+				fmt.Fprintln(lr.out)
+				return "", io.EOF
+			}
+		case keyEnter, '\n':
+			fmt.Fprintln(lr.out)
+			return string(buf), nil
+		case keyBackspace:
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+				redraw()
+			}
+		case keyTab:
+			if lr.completer == nil {
+				continue
+			}
+			candidates := lr.completer(string(buf))
+			if len(candidates) == 1 {
+				buf = []rune(candidates[0])
+				redraw()
+			} else if len(candidates) > 1 {
+				fmt.Fprintln(lr.out)
+				fmt.Fprintln(lr.out, strings.Join(candidates, "  "))
+				redraw()
+			}
+		case keyEscape:
+			// Arrow keys arrive as ESC '[' 'A'/'B'/'C'/'D'.
+			second, _ := reader.ReadByte()
+			if second != '[' {
+				continue
+			}
+			third, _ := reader.ReadByte()
+			switch third {
+			case 'A': // up
+				if histPos > 0 {
+					histPos--
+					buf = []rune(lr.history[histPos])
+					redraw()
+				}
+			case 'B': // down
+				if histPos < len(lr.history)-1 {
+					histPos++
+					buf = []rune(lr.history[histPos])
+					redraw()
+				} else if histPos == len(lr.history)-1 {
+					histPos++
+					buf = nil
+					redraw()
+				}
+			}
+		default:
+			buf = append(buf, r)
+			fmt.Fprint(lr.out, string(r))
+		}
+	}
+}
@@ -1,52 +1,119 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
+	"sync"
+	"time"
 )
 
 type EventLoop struct {
-	ctx     context.Context
-	cancel  context.CancelFunc
-	scanner *bufio.Scanner
+	ctx      context.Context
+	cancel   context.CancelFunc
+	reader   *LineReader
+	registry *Registry
+	vars     map[string]string
+
+	// OnReload is invoked when the process receives SIGHUP, giving
+	// future config-driven features a hook to reload without a
+	// restart.
+	OnReload func()
+
+	mu        sync.Mutex
+	cmdCancel context.CancelFunc
+	cmdCtx    context.Context
+
+	intMu   sync.Mutex
+	lastInt time.Time
 }
 
 func NewEventLoop() *EventLoop {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &EventLoop{
-		ctx:     ctx,
-		cancel:  cancel,
-		scanner: bufio.NewScanner(os.Stdin),
+	el := &EventLoop{
+		ctx:      ctx,
+		cancel:   cancel,
+		registry: NewRegistry(),
+		vars:     make(map[string]string),
 	}
+	registerBuiltins(el)
+	registerExecCommands(el)
+	el.reader = NewLineReader("", el.complete)
+	return el
 }
 
-func (el *EventLoop) Run() error {
-	fmt.Println("Ralphussy Terminal - Press Ctrl+C to exit")
-	fmt.Println()
-
-	sigs := make(chan os.Signal, 1)
-	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+// beginCommand derives a cancelable context for a single command
+// invocation and records its cancel func, so a single SIGINT can stop
+// just the in-flight command instead of the whole loop.
+func (el *EventLoop) beginCommand() context.CancelFunc {
+	ctx, cancel := context.WithCancel(el.ctx)
+
+	el.mu.Lock()
+	el.cmdCtx = ctx
+	el.cmdCancel = cancel
+	el.mu.Unlock()
+
+	return func() {
+		cancel()
+		el.mu.Lock()
+		el.cmdCtx, el.cmdCancel = nil, nil
+		el.mu.Unlock()
+	}
+}
 
-	done := make(chan error, 1)
+// cancelCommand cancels the currently in-flight command, if any.
+func (el *EventLoop) cancelCommand() {
+	el.mu.Lock()
+	cancel := el.cmdCancel
+	el.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
 
-	go func() {
-		done <- el.eventLoop()
-	}()
+// registerInterrupt records a Ctrl+C, whether it arrived as a raw-mode
+// keypress caught by the LineReader at an idle prompt or as a real
+// SIGINT delivered while a command has the terminal in cooked mode. It
+// reports whether this is a second tap within doubleTapWindow of the
+// first, so both sources share one double-tap clock.
+func (el *EventLoop) registerInterrupt() bool {
+	el.intMu.Lock()
+	defer el.intMu.Unlock()
+
+	now := time.Now()
+	if !el.lastInt.IsZero() && now.Sub(el.lastInt) < doubleTapWindow {
+		el.lastInt = time.Time{}
+		return true
+	}
+	el.lastInt = now
+	return false
+}
 
-	select {
-	case err := <-done:
-		return err
-	case <-sigs:
-		fmt.Println("\nReceived interrupt signal, shutting down...")
-		el.cancel()
-		<-done
-		return nil
+// complete returns registered command names and aliases that start
+// with the text typed so far, for the LineReader's tab-completion.
+func (el *EventLoop) complete(line string) []string {
+	var matches []string
+	for _, cmd := range el.registry.All() {
+		if strings.HasPrefix(cmd.Name, line) {
+			matches = append(matches, cmd.Name)
+		}
+		for _, alias := range cmd.Aliases {
+			if strings.HasPrefix(alias, line) {
+				matches = append(matches, alias)
+			}
+		}
 	}
+	return matches
+}
+
+// Register adds cmd to the event loop's command registry, allowing
+// other code in the program to attach commands without editing
+// processCommand.
+func (el *EventLoop) Register(cmd *Command) {
+	el.registry.Register(cmd)
 }
 
 func (el *EventLoop) eventLoop() error {
@@ -55,17 +122,34 @@ func (el *EventLoop) eventLoop() error {
 		case <-el.ctx.Done():
 			return nil
 		default:
-			fmt.Print("> ")
-			if !el.scanner.Scan() {
-				return nil
+			line, err := el.reader.ReadLine(el.ctx, "> ")
+			if err != nil {
+				if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+					return nil
+				}
+				if errors.Is(err, errInterrupted) {
+					if el.registerInterrupt() {
+						fmt.Println("Received second interrupt, shutting down...")
+						el.cancel()
+						return nil
+					}
+					fmt.Println("(press Ctrl+C again within 2s to exit)")
+					continue
+				}
+				return err
 			}
 
-			input := strings.TrimSpace(el.scanner.Text())
+			input := strings.TrimSpace(line)
 			if input == "" {
 				continue
 			}
+			input = el.interpolate(input)
 
 			if err := el.processCommand(input); err != nil {
+				var exitErr *ExitError
+				if errors.As(err, &exitErr) {
+					return exitErr
+				}
 				fmt.Printf("Error: %v\n", err)
 			}
 		}
@@ -73,36 +157,10 @@ func (el *EventLoop) eventLoop() error {
 }
 
 func (el *EventLoop) processCommand(input string) error {
-	parts := strings.Fields(input)
-	if len(parts) == 0 {
-		return nil
-	}
+	done := el.beginCommand()
+	defer done()
 
-	cmd := parts[0]
-	args := parts[1:]
-
-	switch cmd {
-	case "exit", "quit":
-		el.cancel()
-		return nil
-	case "help":
-		el.showHelp()
-	case "echo":
-		fmt.Println(strings.Join(args, " "))
-	default:
-		fmt.Printf("Unknown command: %s\n", cmd)
-		fmt.Println("Type 'help' for available commands")
-	}
-
-	return nil
-}
-
-func (el *EventLoop) showHelp() {
-	fmt.Println("Available commands:")
-	fmt.Println("  help    - Show this help message")
-	fmt.Println("  echo    - Echo back the provided text")
-	fmt.Println("  exit    - Exit the terminal")
-	fmt.Println("  quit    - Exit the terminal")
+	return el.registry.Dispatch(el, input)
 }
 
 func (el *EventLoop) Stop() {
@@ -110,9 +168,38 @@ func (el *EventLoop) Stop() {
 }
 
 func main() {
+	os.Exit(run())
+}
+
+// run builds an EventLoop and drives it in whichever mode the
+// invocation calls for, returning the process exit code.
+func run() int {
+	script, scriptFile, abortOnError := parseFlags(os.Args[1:])
+
 	loop := NewEventLoop()
-	if err := loop.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+
+	switch {
+	case script != "":
+		return loop.RunBatch(strings.NewReader(strings.Join(splitOneShot(script), "\n")), abortOnError)
+	case scriptFile != "":
+		f, err := os.Open(scriptFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		return loop.RunBatch(f, abortOnError)
+	case !isTerminal(os.Stdin):
+		return loop.RunBatch(os.Stdin, abortOnError)
+	default:
+		if err := loop.Run(); err != nil {
+			var exitErr *ExitError
+			if errors.As(err, &exitErr) {
+				return exitErr.Code
+			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
 	}
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// doubleTapWindow is how long a second SIGINT has to follow the first
+// before it's treated as a "really exit" confirmation rather than a
+// request to cancel the in-flight command.
+const doubleTapWindow = 2 * time.Second
+
+func (el *EventLoop) Run() error {
+	fmt.Println("Ralphussy Terminal - Press Ctrl+C to cancel, twice within 2s to exit")
+	fmt.Println()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGTSTP, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- el.eventLoop()
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+		case sig := <-sigs:
+			switch sig {
+			case syscall.SIGINT:
+				if el.registerInterrupt() {
+					fmt.Println("\nReceived second interrupt, shutting down...")
+					el.cancel()
+					<-done
+					return nil
+				}
+				fmt.Println("\nInterrupted (press Ctrl+C again within 2s to exit)")
+				el.cancelCommand()
+			case syscall.SIGTERM:
+				el.cancel()
+				<-done
+				return nil
+			case syscall.SIGTSTP:
+				el.suspend(sigs)
+			case syscall.SIGHUP:
+				if el.OnReload != nil {
+					el.OnReload()
+				}
+			}
+		}
+	}
+}
+
+// suspend restores cooked terminal mode, stops the process with
+// SIGTSTP as if the shell's job control had done it, and re-arms raw
+// mode once a SIGCONT resumes us. SIGTSTP's default action is restored
+// for the self-sent signal, since while it is routed to sigs it would
+// not stop the process, and routing is re-armed after the resume.
+func (el *EventLoop) suspend(sigs chan os.Signal) {
+	el.reader.Suspend()
+	signal.Reset(syscall.SIGTSTP)
+	syscall.Kill(os.Getpid(), syscall.SIGTSTP)
+	signal.Notify(sigs, syscall.SIGTSTP)
+	el.reader.Resume()
+}